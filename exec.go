@@ -0,0 +1,142 @@
+package sshclient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Run runs cmd on a fresh session and waits for it to finish, mirroring
+// os/exec.Cmd.Run(). Unlike ExecScript/Shell, the SSHClient can be reused for
+// further commands afterwards.
+func (s *SSHClient) Run(cmd string) error {
+	session, err := s.newCommandSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	defer s.tagSessionType(session, SessionTypeExec)()
+
+	return session.Run(cmd)
+}
+
+// Output runs cmd on a fresh session and returns its stdout, mirroring
+// os/exec.Cmd.Output().
+func (s *SSHClient) Output(cmd string) ([]byte, error) {
+	session, err := s.newCommandSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+	defer s.tagSessionType(session, SessionTypeExec)()
+
+	out, err := session.Output(cmd)
+	s.metrics().BytesIn(SessionTypeExec, int64(len(out)))
+	return out, err
+}
+
+// CombinedOutput runs cmd on a fresh session and returns its stdout and
+// stderr separately.
+func (s *SSHClient) CombinedOutput(cmd string) ([]byte, []byte, error) {
+	session, err := s.newCommandSession()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer session.Close()
+	defer s.tagSessionType(session, SessionTypeExec)()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	err = session.Run(cmd)
+	s.metrics().BytesIn(SessionTypeExec, int64(stdout.Len()+stderr.Len()))
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// Start starts cmd on a fresh session without waiting for it to finish,
+// mirroring os/exec.Cmd.Start(). Callers must call Wait() to release the
+// session; only one Start() may be outstanding at a time per SSHClient.
+func (s *SSHClient) Start(cmd string) (io.ReadCloser, io.ReadCloser, error) {
+	s.mu.Lock()
+	if s.execSession != nil || s.execStarting {
+		s.mu.Unlock()
+		return nil, nil, errors.New("a command is already running, call Wait() first")
+	}
+	s.execStarting = true
+	s.mu.Unlock()
+
+	clearStarting := func() {
+		s.mu.Lock()
+		s.execStarting = false
+		s.mu.Unlock()
+	}
+
+	session, err := s.newCommandSession()
+	if err != nil {
+		clearStarting()
+		return nil, nil, err
+	}
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		clearStarting()
+		return nil, nil, err
+	}
+	stderr, err := session.StderrPipe()
+	if err != nil {
+		session.Close()
+		clearStarting()
+		return nil, nil, err
+	}
+
+	done := s.tagSessionType(session, SessionTypeExec)
+	if err := session.Start(cmd); err != nil {
+		session.Close()
+		done()
+		clearStarting()
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	s.execSession = session
+	s.execSessionDone = done
+	s.execStarting = false
+	s.mu.Unlock()
+
+	return io.NopCloser(stdout), io.NopCloser(stderr), nil
+}
+
+// Wait waits for the command started by Start() to finish and releases its
+// session, mirroring os/exec.Cmd.Wait().
+func (s *SSHClient) Wait() error {
+	s.mu.Lock()
+	session := s.execSession
+	done := s.execSessionDone
+	s.execSession = nil
+	s.execSessionDone = nil
+	s.mu.Unlock()
+
+	if session == nil {
+		return errors.New("no command running, did you call Start()?")
+	}
+	defer func() {
+		session.Close()
+		done()
+	}()
+
+	return session.Wait()
+}
+
+// newCommandSession opens a fresh session for a one-shot command, leaving the
+// SSHClient's own session (used by ExecScript/Shell) untouched.
+func (s *SSHClient) newCommandSession() (*ssh.Session, error) {
+	client, err := s.getConnectedClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.NewSession()
+}
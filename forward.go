@@ -0,0 +1,141 @@
+package sshclient
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// ForwardAgent forwards the local ssh-agent (as pointed to by SSH_AUTH_SOCK)
+// into the remote session, mirroring OpenSSH's -A flag. Dial() must have been
+// called first.
+func (s *SSHClient) ForwardAgent() error {
+	client, err := s.getConnectedClient()
+	if err != nil {
+		return err
+	}
+	session, err := s.getConnectedSession()
+	if err != nil {
+		return err
+	}
+
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return errors.New("SSH_AUTH_SOCK not set, no local ssh-agent to forward")
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return err
+	}
+
+	if err := agent.ForwardToAgent(client, agent.NewClient(conn)); err != nil {
+		return err
+	}
+
+	return agent.RequestAgentForwarding(session)
+}
+
+// ForwardLocal opens a local TCP listener on localAddr and, for every
+// accepted connection, opens a channel to remoteAddr on the remote host and
+// pipes data between the two, mirroring OpenSSH's -L flag. The returned
+// io.Closer stops the forward when closed.
+func (s *SSHClient) ForwardLocal(localAddr, remoteAddr string) (io.Closer, error) {
+	client, err := s.getConnectedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("tcp", localAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			local, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer local.Close()
+
+				remote, err := client.Dial("tcp", remoteAddr)
+				if err != nil {
+					return
+				}
+				defer remote.Close()
+
+				pipeConns(local, remote)
+			}()
+		}
+	}()
+
+	return ln, nil
+}
+
+// ForwardRemote asks the remote host to listen on remoteAddr and, for every
+// accepted connection, dials localAddr locally and pipes data between the
+// two, mirroring OpenSSH's -R flag. The returned io.Closer stops the forward
+// when closed.
+func (s *SSHClient) ForwardRemote(remoteAddr, localAddr string) (io.Closer, error) {
+	client, err := s.getConnectedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ln, err := client.Listen("tcp", remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			remote, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer remote.Close()
+
+				local, err := net.Dial("tcp", localAddr)
+				if err != nil {
+					return
+				}
+				defer local.Close()
+
+				pipeConns(local, remote)
+			}()
+		}
+	}()
+
+	return ln, nil
+}
+
+// pipeConns copies data in both directions between a and b. As soon as
+// either direction returns (EOF, error, or an idle/half-closed peer), both
+// connections are closed so the other direction's blocked Copy unblocks too,
+// instead of leaking the goroutines and connections until the caller's
+// io.Closer is explicitly closed.
+func pipeConns(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+
+	go func() {
+		io.Copy(a, b)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(b, a)
+		done <- struct{}{}
+	}()
+
+	<-done
+	a.Close()
+	b.Close()
+	<-done
+}
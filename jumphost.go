@@ -0,0 +1,67 @@
+package sshclient
+
+import (
+	"context"
+	"net"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// getClientViaJumpHosts dials through s.JumpHosts in order, opening a
+// direct-tcpip channel from each hop to the next, and finally to hostPort.
+// The intermediate hops' clients are kept in s.jumpClients so Close() can
+// tear the whole chain down.
+func (s *SSHClient) getClientViaJumpHosts(ctx context.Context) error {
+	hops := make([]JumpHostConfig, 0, len(s.JumpHosts)+1)
+	hops = append(hops, s.JumpHosts...)
+	hops = append(hops, JumpHostConfig{HostPort: s.hostPort, SSHConfig: s.sshConfig})
+
+	var current *ssh.Client
+	var jumpClients []*ssh.Client
+	for i, hop := range hops {
+		conn, err := dialHop(ctx, current, hop.HostPort)
+		if err != nil {
+			closeJumpClients(jumpClients)
+			return err
+		}
+
+		c, chans, reqs, err := ssh.NewClientConn(conn, hop.HostPort, &hop.SSHConfig)
+		if err != nil {
+			conn.Close()
+			closeJumpClients(jumpClients)
+			return err
+		}
+
+		next := ssh.NewClient(c, chans, reqs)
+		if i < len(hops)-1 {
+			jumpClients = append(jumpClients, next)
+		}
+		current = next
+	}
+
+	s.mu.Lock()
+	s.jumpClients = jumpClients
+	s.client = current
+	s.mu.Unlock()
+
+	s.metrics().ConnectionOpened()
+	return nil
+}
+
+// dialHop connects to hostPort, either directly (the first hop) or through a
+// direct-tcpip channel opened over an already established hop.
+func dialHop(ctx context.Context, via *ssh.Client, hostPort string) (net.Conn, error) {
+	if via == nil {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", hostPort)
+	}
+	return via.Dial("tcp", hostPort)
+}
+
+// closeJumpClients closes already-established intermediate hops, in reverse
+// dial order, when a later hop in the chain fails to connect.
+func closeJumpClients(clients []*ssh.Client) {
+	for i := len(clients) - 1; i >= 0; i-- {
+		clients[i].Close()
+	}
+}
@@ -0,0 +1,115 @@
+package sshclient
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// startKeepalive launches the keepalive goroutine if KeepaliveInterval is set.
+// It is a no-op otherwise, so callers that never set KeepaliveInterval see no
+// behavior change.
+func (s *SSHClient) startKeepalive() {
+	if s.KeepaliveInterval <= 0 {
+		return
+	}
+
+	if s.KeepaliveTimeout <= 0 {
+		s.KeepaliveTimeout = s.KeepaliveInterval
+	}
+
+	go s.keepaliveLoop()
+}
+
+func (s *SSHClient) keepaliveLoop() {
+	ticker := time.NewTicker(s.KeepaliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.sendKeepalive(); err != nil {
+				s.handleDisconnect()
+				return
+			}
+		}
+	}
+}
+
+func (s *SSHClient) sendKeepalive() error {
+	client := s.currentClient()
+	if client == nil {
+		return errors.New("sshclient not connected")
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(s.KeepaliveTimeout):
+		return errors.New("keepalive timeout")
+	}
+}
+
+// handleDisconnect tears down the dead connection, notifies Disconnected()
+// and, if Reconnect is enabled, redials with ReconnectBackoff until it
+// succeeds or Close() is called.
+func (s *SSHClient) handleDisconnect() {
+	s.mu.Lock()
+	client := s.client
+	s.client = nil
+	s.session = nil
+	s.mu.Unlock()
+
+	if client != nil {
+		client.Close()
+		s.metrics().ConnectionClosed()
+	}
+
+	select {
+	case s.disconnected <- struct{}{}:
+	default:
+	}
+
+	if !s.Reconnect {
+		return
+	}
+
+	backoffCfg := s.reconnectBackoff()
+	backoff := backoffCfg.Min
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-time.After(backoff):
+		}
+
+		if err := s.dial(context.Background()); err == nil {
+			return
+		}
+
+		backoff = time.Duration(float64(backoff) * backoffCfg.Factor)
+		if backoff > backoffCfg.Max {
+			backoff = backoffCfg.Max
+		}
+	}
+}
+
+// reconnectBackoff returns s.ReconnectBackoff, falling back to
+// DefaultReconnectBackoff whenever it is unset or otherwise wouldn't make
+// forward progress (e.g. a zero-value ReconnectBackoff on an SSHClient built
+// without NewSSHClient), to avoid a tight, delay-free redial loop.
+func (s *SSHClient) reconnectBackoff() ReconnectBackoff {
+	b := s.ReconnectBackoff
+	if b.Min <= 0 || b.Max <= 0 || b.Factor <= 1 {
+		return DefaultReconnectBackoff
+	}
+	return b
+}
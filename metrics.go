@@ -0,0 +1,69 @@
+package sshclient
+
+import (
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SessionType identifies the kind of remote session a connection is used for.
+// It is reported to Metrics and, where the server's AcceptEnv configuration
+// allows it, set as the sessionTypeEnvVar environment variable on the remote
+// session -- similar to Coder's CODER_SSH_SESSION_TYPE marker.
+type SessionType string
+
+const (
+	SessionTypeScript SessionType = "script"
+	SessionTypeShell  SessionType = "shell"
+	SessionTypeSFTP   SessionType = "sftp"
+	SessionTypeExec   SessionType = "exec"
+)
+
+// sessionTypeEnvVar is the environment variable name used to tag SessionType
+// on the remote session.
+const sessionTypeEnvVar = "GOSSHCLIENT_SESSION_TYPE"
+
+// Metrics lets callers observe gosshclient's connection and session
+// lifecycle, e.g. to feed a fleet-management dashboard. Implementations must
+// be safe for concurrent use. See PrometheusMetrics for a ready-to-use one.
+type Metrics interface {
+	ConnectionOpened()
+	ConnectionClosed()
+	SessionOpened(t SessionType)
+	SessionClosed(t SessionType, d time.Duration)
+	BytesIn(t SessionType, n int64)
+	BytesOut(t SessionType, n int64)
+}
+
+// noopMetrics is used whenever SSHClient.Metrics is nil, so instrumentation
+// call sites don't need to nil-check.
+type noopMetrics struct{}
+
+func (noopMetrics) ConnectionOpened()                        {}
+func (noopMetrics) ConnectionClosed()                        {}
+func (noopMetrics) SessionOpened(SessionType)                {}
+func (noopMetrics) SessionClosed(SessionType, time.Duration) {}
+func (noopMetrics) BytesIn(SessionType, int64)               {}
+func (noopMetrics) BytesOut(SessionType, int64)              {}
+
+func (s *SSHClient) metrics() Metrics {
+	if s.Metrics == nil {
+		return noopMetrics{}
+	}
+	return s.Metrics
+}
+
+// tagSessionType best-effort tags session with sessionTypeEnvVar and records
+// a SessionOpened with Metrics. The returned func must be deferred by the
+// caller to record the session's end.
+func (s *SSHClient) tagSessionType(session *ssh.Session, t SessionType) func() {
+	// many servers reject arbitrary Setenv via AcceptEnv, same caveat as AddEnv
+	session.Setenv(sessionTypeEnvVar, string(t))
+
+	s.metrics().SessionOpened(t)
+	start := time.Now()
+
+	return func() {
+		s.metrics().SessionClosed(t, time.Since(start))
+	}
+}
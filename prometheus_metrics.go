@@ -0,0 +1,106 @@
+package sshclient
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusMetrics is a ready-to-use Metrics implementation that exposes
+// connection/session counters, byte counters and session duration as a
+// prometheus.Collector. Register it once and assign it to every SSHClient's
+// Metrics field, e.g.:
+//
+//	m := sshclient.NewPrometheusMetrics("myapp")
+//	prometheus.MustRegister(m)
+//	client.Metrics = m
+type PrometheusMetrics struct {
+	connectionsOpened prometheus.Counter
+	connectionsClosed prometheus.Counter
+	sessionsTotal     *prometheus.CounterVec
+	sessionDuration   *prometheus.HistogramVec
+	bytesIn           *prometheus.CounterVec
+	bytesOut          *prometheus.CounterVec
+}
+
+// NewPrometheusMetrics builds a PrometheusMetrics with all metric names
+// prefixed by namespace.
+func NewPrometheusMetrics(namespace string) *PrometheusMetrics {
+	return &PrometheusMetrics{
+		connectionsOpened: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ssh",
+			Name:      "connections_opened_total",
+			Help:      "Total number of ssh connections opened.",
+		}),
+		connectionsClosed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ssh",
+			Name:      "connections_closed_total",
+			Help:      "Total number of ssh connections closed.",
+		}),
+		sessionsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ssh",
+			Name:      "sessions_total",
+			Help:      "Total number of sessions opened, by session type.",
+		}, []string{"session_type"}),
+		sessionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "ssh",
+			Name:      "session_duration_seconds",
+			Help:      "Session duration in seconds, by session type.",
+		}, []string{"session_type"}),
+		bytesIn: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ssh",
+			Name:      "bytes_in_total",
+			Help:      "Total bytes received from the remote host, by session type.",
+		}, []string{"session_type"}),
+		bytesOut: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ssh",
+			Name:      "bytes_out_total",
+			Help:      "Total bytes sent to the remote host, by session type.",
+		}, []string{"session_type"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *PrometheusMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.connectionsOpened.Describe(ch)
+	m.connectionsClosed.Describe(ch)
+	m.sessionsTotal.Describe(ch)
+	m.sessionDuration.Describe(ch)
+	m.bytesIn.Describe(ch)
+	m.bytesOut.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *PrometheusMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.connectionsOpened.Collect(ch)
+	m.connectionsClosed.Collect(ch)
+	m.sessionsTotal.Collect(ch)
+	m.sessionDuration.Collect(ch)
+	m.bytesIn.Collect(ch)
+	m.bytesOut.Collect(ch)
+}
+
+func (m *PrometheusMetrics) ConnectionOpened() { m.connectionsOpened.Inc() }
+func (m *PrometheusMetrics) ConnectionClosed() { m.connectionsClosed.Inc() }
+
+func (m *PrometheusMetrics) SessionOpened(t SessionType) {
+	m.sessionsTotal.WithLabelValues(string(t)).Inc()
+}
+
+func (m *PrometheusMetrics) SessionClosed(t SessionType, d time.Duration) {
+	m.sessionDuration.WithLabelValues(string(t)).Observe(d.Seconds())
+}
+
+func (m *PrometheusMetrics) BytesIn(t SessionType, n int64) {
+	m.bytesIn.WithLabelValues(string(t)).Add(float64(n))
+}
+
+func (m *PrometheusMetrics) BytesOut(t SessionType, n int64) {
+	m.bytesOut.WithLabelValues(string(t)).Add(float64(n))
+}
@@ -0,0 +1,122 @@
+package sshclient
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/sftp"
+)
+
+// NewSFTPClient returns an sftp.Client that reuses the underlying *ssh.Client
+// connection. The returned client shares its lifecycle with the SSHClient:
+// callers should not call sftp.Client.Close() themselves, Close() on the
+// SSHClient takes care of that.
+func (s *SSHClient) NewSFTPClient() (*sftp.Client, error) {
+	s.mu.Lock()
+	if s.sftpClient != nil {
+		c := s.sftpClient
+		s.mu.Unlock()
+		return c, nil
+	}
+	s.mu.Unlock()
+
+	client, err := s.getConnectedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	done := s.tagSessionType(session, SessionTypeSFTP)
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		done()
+		return nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		done()
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		session.Close()
+		done()
+		return nil, err
+	}
+
+	c, err := sftp.NewClientPipe(stdout, stdin)
+	if err != nil {
+		session.Close()
+		done()
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.sftpClient != nil {
+		// lost the race against a concurrent NewSFTPClient call
+		existing := s.sftpClient
+		s.mu.Unlock()
+		done()
+		session.Close()
+		return existing, nil
+	}
+	s.sftpSessionDone = done
+	s.sftpSession = session
+	s.sftpClient = c
+	s.mu.Unlock()
+
+	return c, nil
+}
+
+// Upload copies the local file at localPath to remotePath on the remote host.
+func (s *SSHClient) Upload(localPath, remotePath string) error {
+	c, err := s.NewSFTPClient()
+	if err != nil {
+		return err
+	}
+
+	local, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer local.Close()
+
+	remote, err := c.Create(remotePath)
+	if err != nil {
+		return err
+	}
+	defer remote.Close()
+
+	n, err := io.Copy(remote, local)
+	s.metrics().BytesOut(SessionTypeSFTP, n)
+	return err
+}
+
+// Download copies the remote file at remotePath to localPath.
+func (s *SSHClient) Download(remotePath, localPath string) error {
+	c, err := s.NewSFTPClient()
+	if err != nil {
+		return err
+	}
+
+	remote, err := c.Open(remotePath)
+	if err != nil {
+		return err
+	}
+	defer remote.Close()
+
+	local, err := os.Create(localPath)
+	if err != nil {
+		return err
+	}
+	defer local.Close()
+
+	n, err := io.Copy(local, remote)
+	s.metrics().BytesIn(SessionTypeSFTP, n)
+	return err
+}
@@ -10,34 +10,110 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/pkg/sftp"
 )
 
+// ReconnectBackoff configures the exponential backoff used between redial
+// attempts once a keepalive timeout tears down the connection.
+type ReconnectBackoff struct {
+	Min    time.Duration
+	Max    time.Duration
+	Factor float64
+}
+
+// DefaultReconnectBackoff is used when an SSHClient has Reconnect enabled but
+// no ReconnectBackoff was set.
+var DefaultReconnectBackoff = ReconnectBackoff{
+	Min:    time.Second,
+	Max:    30 * time.Second,
+	Factor: 2,
+}
+
 // SSHClient represents a high level ssh client
 type SSHClient struct {
 	hostPort  string
 	sshConfig ssh.ClientConfig
-	client    *ssh.Client
-	session   *ssh.Session
-	done      chan struct{}
+
+	// mu guards client and session: both are read by the public API and by
+	// the keepalive goroutine, and written by Close()/handleDisconnect()'s
+	// reconnect, possibly from a different goroutine.
+	mu              sync.Mutex
+	client          *ssh.Client
+	session         *ssh.Session
+	sftpClient      *sftp.Client
+	execSession     *ssh.Session
+	execSessionDone func()
+	// execStarting is set while Start() is opening a new exec session, before
+	// execSession itself is assigned, so a concurrent Start() call is
+	// rejected instead of racing to overwrite execSession.
+	execStarting bool
+	done         chan struct{}
+
+	// KeepaliveInterval, if non-zero, enables periodic keepalive@openssh.com
+	// global requests once the connection is established.
+	KeepaliveInterval time.Duration
+	// KeepaliveTimeout is how long to wait for a keepalive reply before
+	// considering the connection dead. Defaults to KeepaliveInterval if unset.
+	KeepaliveTimeout time.Duration
+	// Reconnect enables automatic redial with ReconnectBackoff after a
+	// keepalive timeout closes the connection.
+	Reconnect        bool
+	ReconnectBackoff ReconnectBackoff
+
+	disconnected chan struct{}
+
+	// JumpHosts chains bastion hosts to connect through before reaching
+	// hostPort, replicating OpenSSH's ProxyJump/-J behavior. Each hop opens a
+	// direct-tcpip channel to the next, and the last hop's channel carries the
+	// connection to hostPort.
+	JumpHosts   []JumpHostConfig
+	jumpClients []*ssh.Client
+
+	// Metrics, if set, is notified of the connection and session lifecycle.
+	// A nil Metrics (the default) disables instrumentation.
+	Metrics Metrics
+
+	sftpSession     *ssh.Session
+	sftpSessionDone func()
+}
+
+// JumpHostConfig describes one bastion hop in SSHClient.JumpHosts.
+type JumpHostConfig struct {
+	HostPort  string
+	SSHConfig ssh.ClientConfig
 }
 
 // NewSSHClient returns a high level ssh client
 func NewSSHClient(hostPort string, sshconfig ssh.ClientConfig) *SSHClient {
 	return &SSHClient{
-		hostPort:  hostPort,
-		sshConfig: sshconfig,
-		done:      make(chan struct{}),
+		hostPort:         hostPort,
+		sshConfig:        sshconfig,
+		done:             make(chan struct{}),
+		ReconnectBackoff: DefaultReconnectBackoff,
+		disconnected:     make(chan struct{}, 1),
 	}
 }
 
+// Disconnected is signaled whenever the keepalive goroutine detects a dead
+// connection, whether or not Reconnect is enabled.
+func (s *SSHClient) Disconnected() <-chan struct{} {
+	return s.disconnected
+}
+
 func (s *SSHClient) getClient(ctx context.Context) error {
-	if s.client != nil {
+	if s.currentClient() != nil {
 		return nil
 	}
 
+	if len(s.JumpHosts) > 0 {
+		return s.getClientViaJumpHosts(ctx)
+	}
+
 	var d net.Dialer
 	conn, err := d.DialContext(ctx, "tcp", s.hostPort)
 	if err != nil {
@@ -66,23 +142,72 @@ func (s *SSHClient) getClient(ctx context.Context) error {
 		return err
 	}
 
-	s.client = ssh.NewClient(c, chans, reqs)
+	s.setClient(ssh.NewClient(c, chans, reqs))
+	s.metrics().ConnectionOpened()
 	return nil
 }
 
 func (s *SSHClient) getSession() error {
+	s.mu.Lock()
 	if s.session != nil {
+		s.mu.Unlock()
 		return nil
 	}
+	client := s.client
+	s.mu.Unlock()
 
-	session, err := s.client.NewSession()
+	if client == nil {
+		return errors.New("sshclient not connected, did you call Dial()?")
+	}
+
+	session, err := client.NewSession()
 	if err != nil {
 		return err
 	}
+
+	s.mu.Lock()
 	s.session = session
+	s.mu.Unlock()
 	return nil
 }
 
+// currentClient returns the connected *ssh.Client, or nil if not connected.
+func (s *SSHClient) currentClient() *ssh.Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.client
+}
+
+// setClient atomically assigns s.client.
+func (s *SSHClient) setClient(c *ssh.Client) {
+	s.mu.Lock()
+	s.client = c
+	s.mu.Unlock()
+}
+
+// getConnectedClient returns the connected *ssh.Client, erroring out if Dial()
+// has not (yet) succeeded. Taking the snapshot under s.mu avoids a TOCTOU
+// race against the keepalive goroutine tearing the connection down.
+func (s *SSHClient) getConnectedClient() (*ssh.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == nil {
+		return nil, errors.New("sshclient not connected, did you call Dial()?")
+	}
+	return s.client, nil
+}
+
+// getConnectedSession returns the SSHClient's own session (used by
+// ExecScript/Shell), erroring out if Dial() has not (yet) succeeded.
+func (s *SSHClient) getConnectedSession() (*ssh.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session == nil {
+		return nil, errors.New("sshclient not connected, did you call Dial()?")
+	}
+	return s.session, nil
+}
+
 // Dial creates an ssh client as well as its session
 // After a successful call to Dial(), one should also always call Close()
 func (s *SSHClient) Dial() error {
@@ -110,14 +235,19 @@ func (s *SSHClient) dial(ctx context.Context) error {
 		return fmt.Errorf("session error: %v", err)
 	}
 
+	s.startKeepalive()
+
 	return nil
 }
 
-func (s *SSHClient) mustBeConnected() error {
-	if s.session == nil || s.client == nil {
-		return errors.New("sshclient not connected, did you call Dial()?")
+// clearSession resets s.session to nil, but only if it still points at
+// session (it may already have been cleared by Close()/handleDisconnect()).
+func (s *SSHClient) clearSession(session *ssh.Session) {
+	s.mu.Lock()
+	if s.session == session {
+		s.session = nil
 	}
-	return nil
+	s.mu.Unlock()
 }
 
 // Close closes the underlying ssh session and client
@@ -130,60 +260,91 @@ func (s *SSHClient) Close() error {
 		}
 	}()
 
-	if s.session != nil {
-		if err := s.session.Wait(); err != nil {
+	s.mu.Lock()
+	session := s.session
+	s.session = nil
+	sftpClient := s.sftpClient
+	s.sftpClient = nil
+	sftpSessionDone := s.sftpSessionDone
+	s.sftpSessionDone = nil
+	s.sftpSession = nil
+	client := s.client
+	s.client = nil
+	jumpClients := s.jumpClients
+	s.jumpClients = nil
+	s.mu.Unlock()
+
+	if session != nil {
+		if err := session.Wait(); err != nil {
 			return err
 		}
-		s.session = nil
 	}
-	if s.client != nil {
-		if err := s.client.Close(); err != nil {
+	if sftpClient != nil {
+		if err := sftpClient.Close(); err != nil {
+			return err
+		}
+		if sftpSessionDone != nil {
+			sftpSessionDone()
+		}
+	}
+	if client != nil {
+		if err := client.Close(); err != nil {
+			return err
+		}
+		s.metrics().ConnectionClosed()
+	}
+	for i := len(jumpClients) - 1; i >= 0; i-- {
+		if err := jumpClients[i].Close(); err != nil {
 			return err
 		}
-		s.client = nil
 	}
 
 	return nil
 }
 
 func (s *SSHClient) stdinPipe() (io.WriteCloser, error) {
-	if err := s.mustBeConnected(); err != nil {
+	session, err := s.getConnectedSession()
+	if err != nil {
 		return nil, err
 	}
-	return s.session.StdinPipe()
+	return session.StdinPipe()
 }
 
 // StdoutPipe creates an ssh.session if it does not exist and calls StdoutPipe on it.
 func (s *SSHClient) StdoutPipe() (io.Reader, error) {
-	if err := s.mustBeConnected(); err != nil {
+	session, err := s.getConnectedSession()
+	if err != nil {
 		return nil, err
 	}
-	return s.session.StdoutPipe()
+	return session.StdoutPipe()
 }
 
 // StderrPipe creates an ssh.session if it does not exist and calls StderrPipe on it.
 func (s *SSHClient) StderrPipe() (io.Reader, error) {
-	if err := s.mustBeConnected(); err != nil {
+	session, err := s.getConnectedSession()
+	if err != nil {
 		return nil, err
 	}
-	return s.session.StderrPipe()
+	return session.StderrPipe()
 }
 
 // ExecScript executes a (shell) script line by line.
 // After return, you can not re-use the sshclient
 func (s *SSHClient) ExecScript(script string) error {
-	if err := s.mustBeConnected(); err != nil {
+	session, err := s.getConnectedSession()
+	if err != nil {
 		return err
 	}
 	// users are supposed to call Close(), but to be sure...
 	defer s.Close()
+	defer s.tagSessionType(session, SessionTypeScript)()
 
-	inp, err := s.stdinPipe()
+	inp, err := session.StdinPipe()
 	if err != nil {
 		return err
 	}
 
-	if err := s.session.Shell(); err != nil {
+	if err := session.Shell(); err != nil {
 		return err
 	}
 	if _, err := fmt.Fprintln(inp, script); err != nil {
@@ -191,8 +352,8 @@ func (s *SSHClient) ExecScript(script string) error {
 	}
 
 	inp.Close()
-	err = s.session.Wait()
-	s.session = nil
+	err = session.Wait()
+	s.clearSession(session)
 
 	return err
 }
@@ -200,11 +361,13 @@ func (s *SSHClient) ExecScript(script string) error {
 // Shell executes an interactive ssh shell
 // After return, you can not re-use the sshclient
 func (s *SSHClient) Shell() error {
-	if err := s.mustBeConnected(); err != nil {
+	session, err := s.getConnectedSession()
+	if err != nil {
 		return err
 	}
 	// users are supposed to call Close(), but to be sure...
 	defer s.Close()
+	defer s.tagSessionType(session, SessionTypeShell)()
 
 	fd := int(os.Stdin.Fd())
 	state, err := terminal.MakeRaw(fd)
@@ -224,15 +387,15 @@ func (s *SSHClient) Shell() error {
 		ssh.TTY_OP_OSPEED: 14400,
 	}
 
-	if err = s.session.RequestPty("xterm", h, w, modes); err != nil {
+	if err = session.RequestPty("xterm", h, w, modes); err != nil {
 		return err
 	}
 
-	s.session.Stdin = os.Stdin
-	s.session.Stdout = os.Stdout
-	s.session.Stderr = os.Stderr
+	session.Stdin = os.Stdin
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
 
-	if err := s.session.Shell(); err != nil {
+	if err := session.Shell(); err != nil {
 		return err
 	}
 
@@ -249,13 +412,13 @@ func (s *SSHClient) Shell() error {
 			case syscall.SIGWINCH:
 				fd := int(os.Stdout.Fd())
 				w, h, _ = terminal.GetSize(fd)
-				s.session.WindowChange(h, w)
+				session.WindowChange(h, w)
 			}
 		}
 	}()
 
-	err = s.session.Wait()
-	s.session = nil
+	err = session.Wait()
+	s.clearSession(session)
 
 	close(sigChan)
 	wg.Wait()